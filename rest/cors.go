@@ -2,11 +2,14 @@ package rest
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 // CorsMiddleware provides a configurable CORS implementation.
 type CorsMiddleware struct {
+	setupOnce      sync.Once
 	allowedMethods map[string]bool
 	allowedHeaders map[string]bool
 
@@ -16,8 +19,15 @@ type CorsMiddleware struct {
 	// Function excecuted for every CORS requests to validate the Origin. (Required)
 	// Must return true if valid, false if invalid.
 	// For instance: simple equality, regexp, DB lookup, ...
+	// Ignored if AllowedOrigins is set.
 	OriginValidator func(origin string, request *Request) bool
 
+	// Shortcut for OriginValidator: list of origins allowed to make CORS
+	// requests. A single "*" allows any origin, mirroring the common
+	// convention used by other CORS libraries. When set, it takes
+	// precedence over OriginValidator.
+	AllowedOrigins []string
+
 	// List of allowed HTTP methods. Note that the comparison will be made in uppercase
 	// to avoid common mistakes. And that the Access-Control-Allow-Methods response header
 	// also uses uppercase.
@@ -30,11 +40,85 @@ type CorsMiddleware struct {
 	// (see CorsInfo.AccessControlRequestHeaders)
 	AllowedHeaders []string
 
+	// List of headers that the browser is allowed to expose to the CORS
+	// request's issuer, set on the Access-Control-Expose-Headers response
+	// header of actual (non-preflight) requests. Omitted when empty.
+	ExposedHeaders []string
+
 	// User to se the Access-Control-Allow-Credentials response header.
 	AccessControlAllowCredentials bool
 
 	// Used to set the Access-Control-Max-Age response header, in seconds.
+	// Omitted when 0.
 	AccessControlMaxAge int
+
+	// When true, a preflight OPTIONS request is forwarded to the wrapped
+	// handler (after the CORS headers have been written) instead of being
+	// terminated by the middleware. Useful for applications that register
+	// their own OPTIONS routes.
+	OptionsPassthrough bool
+
+	// Status code written to terminate a preflight request when
+	// OptionsPassthrough is false. Defaults to http.StatusOK (200); some
+	// legacy XHR clients and IE expect 204 instead.
+	OptionsSuccessStatus int
+}
+
+// setup lazily builds the allowedMethods/allowedHeaders lookup maps exactly
+// once, so concurrent first requests don't race on their initialization.
+func (mw *CorsMiddleware) setup() {
+	mw.setupOnce.Do(func() {
+		mw.allowedMethods = map[string]bool{}
+		for _, allowedMethod := range mw.AllowedMethods {
+			mw.allowedMethods[strings.ToUpper(allowedMethod)] = true
+		}
+		mw.allowedHeaders = map[string]bool{}
+		for _, allowedHeader := range mw.AllowedHeaders {
+			mw.allowedHeaders[http.CanonicalHeaderKey(allowedHeader)] = true
+		}
+	})
+}
+
+// optionsSuccessStatus returns the configured OptionsSuccessStatus, or its
+// default of http.StatusOK when unset.
+func (mw *CorsMiddleware) optionsSuccessStatus() int {
+	if mw.OptionsSuccessStatus == 0 {
+		return http.StatusOK
+	}
+	return mw.OptionsSuccessStatus
+}
+
+// isOriginAllowed returns true if origin is allowed by AllowedOrigins, either
+// via an exact match or because AllowedOrigins contains the wildcard "*".
+func (mw *CorsMiddleware) isOriginAllowed(origin string) bool {
+	for _, allowedOrigin := range mw.AllowedOrigins {
+		if allowedOrigin == "*" || allowedOrigin == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsWildcardOrigin returns true if AllowedOrigins explicitly allows any
+// origin via "*".
+func (mw *CorsMiddleware) allowsWildcardOrigin() bool {
+	for _, allowedOrigin := range mw.AllowedOrigins {
+		if allowedOrigin == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// allowOriginValue computes the value to send as Access-Control-Allow-Origin
+// for the given request Origin. Per spec, "*" cannot be combined with
+// Access-Control-Allow-Credentials, so the Origin is echoed back instead in
+// that case.
+func (mw *CorsMiddleware) allowOriginValue(origin string) string {
+	if mw.allowsWildcardOrigin() && !mw.AccessControlAllowCredentials {
+		return "*"
+	}
+	return origin
 }
 
 func (mw *CorsMiddleware) MiddlewareFunc(handler HandlerFunc) HandlerFunc {
@@ -53,35 +137,36 @@ func (mw *CorsMiddleware) MiddlewareFunc(handler HandlerFunc) HandlerFunc {
 			return
 		}
 
+		// Vary must be set on every CORS response, including rejections below,
+		// so that shared caches don't serve a response computed for one Origin
+		// (or one preflight method/header set) to a different one.
+		writer.Header().Add("Vary", "Origin")
+		if corsInfo.IsPreflight {
+			writer.Header().Add("Vary", "Access-Control-Request-Method, Access-Control-Request-Headers")
+		}
+
 		// Validate the Origin
-		if mw.OriginValidator(corsInfo.Origin, request) == false {
+		if len(mw.AllowedOrigins) > 0 {
+			if !mw.isOriginAllowed(corsInfo.Origin) {
+				Error(writer, "Invalid Origin", http.StatusForbidden)
+				return
+			}
+		} else if mw.OriginValidator(corsInfo.Origin, request) == false {
 			Error(writer, "Invalid Origin", http.StatusForbidden)
 			return
 		}
 
 		if corsInfo.IsPreflight {
 
-			// check the request methods
-			if mw.allowedMethods == nil {
-				mw.allowedMethods = map[string]bool{}
-				for _, allowedMethod := range mw.AllowedMethods {
-					mw.allowedMethods[strings.ToUpper(allowedMethod)] = true
-				}
+			mw.setup()
 
-			}
+			// check the request methods
 			if mw.allowedMethods[corsInfo.AccessControlRequestMethod] == false {
 				Error(writer, "Invalid Preflight Request", http.StatusForbidden)
 				return
 			}
 
 			// check the request headers
-			if mw.allowedHeaders == nil {
-				mw.allowedHeaders = map[string]bool{}
-				for _, allowedHeader := range mw.AllowedHeaders {
-					mw.allowedHeaders[http.CanonicalHeaderKey(allowedHeader)] = true
-				}
-
-			}
 			for _, requestedHeader := range corsInfo.AccessControlRequestHeaders {
 				if mw.allowedHeaders[requestedHeader] == false {
 					Error(writer, "Invalid Preflight Request", http.StatusForbidden)
@@ -95,16 +180,26 @@ func (mw *CorsMiddleware) MiddlewareFunc(handler HandlerFunc) HandlerFunc {
 			for allowedHeader, _ := range mw.allowedHeaders {
 				writer.Header().Add("Access-Control-Allow-Headers", allowedHeader)
 			}
-			writer.Header().Set("Access-Control-Allow-Origin", corsInfo.Origin)
+			writer.Header().Set("Access-Control-Allow-Origin", mw.allowOriginValue(corsInfo.Origin))
 			if mw.AccessControlAllowCredentials == true {
 				writer.Header().Set("Access-Control-Allow-Credentials", "true")
 			}
-			writer.Header().Set("Access-Control-Max-Age", string(mw.AccessControlMaxAge))
-			writer.WriteHeader(http.StatusOK)
+			if mw.AccessControlMaxAge != 0 {
+				writer.Header().Set("Access-Control-Max-Age", strconv.Itoa(mw.AccessControlMaxAge))
+			}
+
+			if mw.OptionsPassthrough {
+				// continue, execute the wrapped middleware
+				handler(writer, request)
+				return
+			}
+			writer.WriteHeader(mw.optionsSuccessStatus())
 			return
 		} else {
-			writer.Header().Set("Access-Control-Expose-Headers", "X-Powered-By") // TODO
-			writer.Header().Set("Access-Control-Allow-Origin", corsInfo.Origin)
+			if len(mw.ExposedHeaders) > 0 {
+				writer.Header().Set("Access-Control-Expose-Headers", strings.Join(mw.ExposedHeaders, ", "))
+			}
+			writer.Header().Set("Access-Control-Allow-Origin", mw.allowOriginValue(corsInfo.Origin))
 			if mw.AccessControlAllowCredentials == true {
 				writer.Header().Set("Access-Control-Allow-Credentials", "true")
 			}
@@ -0,0 +1,105 @@
+package rest
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// defaultOriginValidatorSchemes is used by origin validator helpers that
+// don't take an explicit list of schemes.
+var defaultOriginValidatorSchemes = []string{"http", "https"}
+
+// NewListOriginValidator returns an OriginValidator that accepts an Origin
+// if it matches one of the given origins exactly, case-insensitively. This
+// also covers the literal "null" origin sent by browsers for sandboxed
+// iframes and file:// documents, simply by including "null" in origins.
+func NewListOriginValidator(origins ...string) func(origin string, request *Request) bool {
+	normalized := make([]string, len(origins))
+	for i, allowedOrigin := range origins {
+		normalized[i] = strings.ToLower(allowedOrigin)
+	}
+	return func(origin string, request *Request) bool {
+		origin = strings.ToLower(origin)
+		for _, allowedOrigin := range normalized {
+			if allowedOrigin == origin {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// NewRegexpOriginValidator returns an OriginValidator that accepts an Origin
+// if it matches one of the given regular expressions. Patterns are matched
+// against the full Origin header value (scheme, host and optional port), so
+// callers should anchor their patterns (e.g. "^https://") when a partial
+// match isn't intended.
+func NewRegexpOriginValidator(patterns ...string) func(origin string, request *Request) bool {
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		compiled[i] = regexp.MustCompile(pattern)
+	}
+	return func(origin string, request *Request) bool {
+		for _, re := range compiled {
+			if re.MatchString(origin) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// NewSuffixOriginValidator returns an OriginValidator that accepts an Origin
+// whose host matches one of the given domains, using http and https as the
+// allowed schemes. A domain of the form "*.example.com" matches any direct
+// or indirect subdomain of example.com (but not example.com itself); a plain
+// domain like "example.com" matches only that exact host. See
+// NewSuffixOriginValidatorWithSchemes to restrict or extend the allowed
+// schemes.
+func NewSuffixOriginValidator(domains ...string) func(origin string, request *Request) bool {
+	return NewSuffixOriginValidatorWithSchemes(defaultOriginValidatorSchemes, domains...)
+}
+
+// NewSuffixOriginValidatorWithSchemes is like NewSuffixOriginValidator but
+// additionally requires the Origin's scheme to be one of schemes (compared
+// case-insensitively). Domains are matched against the host parsed out of
+// the Origin, without scheme or port, so an origin such as
+// "http://evil.example.com.attacker.tld" matches neither "example.com" nor
+// "*.example.com".
+func NewSuffixOriginValidatorWithSchemes(schemes []string, domains ...string) func(origin string, request *Request) bool {
+	allowedSchemes := make(map[string]bool, len(schemes))
+	for _, scheme := range schemes {
+		allowedSchemes[strings.ToLower(scheme)] = true
+	}
+	return func(origin string, request *Request) bool {
+		parsed, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+		if !allowedSchemes[strings.ToLower(parsed.Scheme)] {
+			return false
+		}
+		host := strings.ToLower(parsed.Hostname())
+		for _, domain := range domains {
+			if hostMatchesSuffixDomain(host, strings.ToLower(domain)) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// hostMatchesSuffixDomain reports whether host matches domain, where domain
+// may be a plain host ("example.com", matched exactly) or a wildcard
+// subdomain pattern ("*.example.com", matched by any host ending in
+// ".example.com"). For a wildcard pattern the base domain must be preceded
+// by a "." in the host, so neither "example.com" itself nor
+// "evil-example.com" matches "*.example.com".
+func hostMatchesSuffixDomain(host, domain string) bool {
+	if strings.HasPrefix(domain, "*.") {
+		base := domain[2:]
+		return host != base && strings.HasSuffix(host, "."+base)
+	}
+	return host == domain
+}
@@ -0,0 +1,61 @@
+package rest
+
+import "testing"
+
+func TestNewSuffixOriginValidatorExactMatch(t *testing.T) {
+	validate := NewSuffixOriginValidator("example.com")
+
+	if !validate("https://example.com", nil) {
+		t.Errorf("expected https://example.com to match domain example.com")
+	}
+	if validate("https://other.com", nil) {
+		t.Errorf("expected https://other.com not to match domain example.com")
+	}
+}
+
+func TestNewSuffixOriginValidatorWildcardSubdomain(t *testing.T) {
+	validate := NewSuffixOriginValidator("*.example.com")
+
+	if !validate("https://api.example.com", nil) {
+		t.Errorf("expected https://api.example.com to match *.example.com")
+	}
+	if !validate("https://deeply.nested.example.com", nil) {
+		t.Errorf("expected https://deeply.nested.example.com to match *.example.com")
+	}
+	if validate("https://example.com", nil) {
+		t.Errorf("expected bare https://example.com not to match *.example.com")
+	}
+}
+
+func TestNewSuffixOriginValidatorRejectsAttackerSuffixBypass(t *testing.T) {
+	validate := NewSuffixOriginValidator("example.com")
+
+	if validate("http://evil.example.com.attacker.tld", nil) {
+		t.Errorf("expected http://evil.example.com.attacker.tld not to match domain example.com")
+	}
+	if validate("http://evilexample.com", nil) {
+		t.Errorf("expected http://evilexample.com not to match domain example.com (missing dot boundary)")
+	}
+}
+
+func TestNewSuffixOriginValidatorRejectsDisallowedScheme(t *testing.T) {
+	validate := NewSuffixOriginValidator("example.com")
+
+	if validate("ftp://example.com", nil) {
+		t.Errorf("expected ftp://example.com to be rejected by the default http/https scheme allow-list")
+	}
+}
+
+func TestNewListOriginValidatorCaseInsensitiveAndNull(t *testing.T) {
+	validate := NewListOriginValidator("https://Example.com", "null")
+
+	if !validate("https://example.com", nil) {
+		t.Errorf("expected case-insensitive match of https://example.com")
+	}
+	if !validate("NULL", nil) {
+		t.Errorf("expected case-insensitive match of the null origin")
+	}
+	if validate("https://other.com", nil) {
+		t.Errorf("expected https://other.com not to match the allow-list")
+	}
+}
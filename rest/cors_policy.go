@@ -0,0 +1,89 @@
+package rest
+
+import (
+	"net/http"
+)
+
+// CorsPolicy associates a CorsMiddleware configuration with a route.
+//
+// HttpMethod and PathExp use the exact same syntax as Route.HttpMethod and
+// Route.PathExp (":name" params, trailing "*splat"), because a CorsPolicy is
+// compiled into a Route and matched through MakeRouter/Router.FindRoute —
+// the same router used to dispatch real requests. This guarantees a
+// CorsPolicy matches precisely the same requests its PathExp would match as
+// a real route, instead of drifting from the app's own precedence and
+// wildcard rules. Unlike App routes, HttpMethod must be a single concrete
+// method (e.g. "GET"); register one CorsPolicy per method if more than one
+// needs the same PathExp.
+type CorsPolicy struct {
+	HttpMethod string
+	PathExp    string
+
+	// Middleware applied to requests matching this policy.
+	Middleware *CorsMiddleware
+}
+
+// CorsPolicySet lets an application register a different CorsMiddleware per
+// route pattern / HTTP method, instead of a single global policy. This is
+// useful for multi-tenant APIs that expose, for instance, a public GET
+// endpoint with a wildcard origin alongside a credentialed, origin-restricted
+// API and an internal admin section that rejects cross-origin traffic
+// entirely.
+type CorsPolicySet struct {
+	// RejectUnmatchedRequests rejects requests that don't match any
+	// registered CorsPolicy, whether they are CORS requests or not. When
+	// false (the default), unmatched requests are passed through to the
+	// wrapped handler untouched.
+	RejectUnmatchedRequests bool
+
+	router        Router
+	policyByRoute map[*Route]*CorsPolicy
+}
+
+// SetPolicies compiles policies into a Router via MakeRouter, exactly as
+// App.SetRoutes compiles an app's Route table, and must be called before the
+// CorsPolicySet is used. It returns an error if the underlying router
+// rejects the route table (e.g. a PathExp conflict), mirroring MakeRouter.
+func (ps *CorsPolicySet) SetPolicies(policies ...*CorsPolicy) error {
+	routes := make([]*Route, len(policies))
+	policyByRoute := make(map[*Route]*CorsPolicy, len(policies))
+	for i, policy := range policies {
+		route := &Route{
+			HttpMethod: policy.HttpMethod,
+			PathExp:    policy.PathExp,
+			Func:       func(writer ResponseWriter, request *Request) {},
+		}
+		routes[i] = route
+		policyByRoute[route] = policy
+	}
+
+	router, err := MakeRouter(routes...)
+	if err != nil {
+		return err
+	}
+
+	ps.router = router
+	ps.policyByRoute = policyByRoute
+	return nil
+}
+
+// MiddlewareFunc dispatches each request to the CorsMiddleware of the
+// CorsPolicy whose Route matches via the router built by SetPolicies.
+// Requests that match no policy are passed through to the wrapped handler,
+// unless RejectUnmatchedRequests is set.
+func (ps *CorsPolicySet) MiddlewareFunc(handler HandlerFunc) HandlerFunc {
+	return func(writer ResponseWriter, request *Request) {
+		route, _, err := ps.router.FindRoute(request.Method, request.URL.Path)
+		if err != nil || route == nil {
+			if ps.RejectUnmatchedRequests {
+				Error(writer, "No matching CORS policy", http.StatusForbidden)
+				return
+			}
+			handler(writer, request)
+			return
+		}
+
+		policy := ps.policyByRoute[route]
+		policy.Middleware.MiddlewareFunc(handler)(writer, request)
+	}
+}
@@ -0,0 +1,97 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeRouter is a stand-in for the real trie-based Router, so these tests
+// pin down CorsPolicySet's own dispatch logic (matched / unmatched /
+// reject-unmatched) without depending on, or duplicating, the router's own
+// precedence and wildcard tests.
+type fakeRouter struct {
+	route  *Route
+	params map[string]string
+	err    error
+}
+
+func (r *fakeRouter) FindRoute(httpMethod string, pathString string) (*Route, map[string]string, error) {
+	return r.route, r.params, r.err
+}
+
+func newTestRequest(method, path string) *Request {
+	return &Request{Request: httptest.NewRequest(method, path, nil)}
+}
+
+func TestCorsPolicySetDispatchesToMatchedPolicy(t *testing.T) {
+	called := false
+	route := &Route{HttpMethod: "GET", PathExp: "/public/*rest"}
+	policy := &CorsPolicy{
+		HttpMethod: "GET",
+		PathExp:    "/public/*rest",
+		Middleware: &CorsMiddleware{AllowedOrigins: []string{"*"}},
+	}
+	ps := &CorsPolicySet{
+		router:        &fakeRouter{route: route},
+		policyByRoute: map[*Route]*CorsPolicy{route: policy},
+	}
+
+	handler := ps.MiddlewareFunc(func(w ResponseWriter, r *Request) {
+		called = true
+	})
+
+	w := httptest.NewRecorder()
+	request := newTestRequest("GET", "http://api.example.com/public/assets/app.js")
+	request.Header.Set("Origin", "http://example.com")
+	handler(&responseWriter{ResponseWriter: w}, request)
+
+	if !called {
+		t.Fatalf("expected the wrapped handler to be called for a matched policy")
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("expected Access-Control-Allow-Origin %q, got %q", "*", got)
+	}
+}
+
+func TestCorsPolicySetPassesThroughUnmatchedByDefault(t *testing.T) {
+	called := false
+	ps := &CorsPolicySet{router: &fakeRouter{route: nil}}
+
+	handler := ps.MiddlewareFunc(func(w ResponseWriter, r *Request) {
+		called = true
+	})
+
+	w := httptest.NewRecorder()
+	request := newTestRequest("GET", "/unknown")
+	request.Header.Set("Origin", "http://example.com")
+	handler(&responseWriter{ResponseWriter: w}, request)
+
+	if !called {
+		t.Fatalf("expected the wrapped handler to be called for an unmatched request")
+	}
+}
+
+func TestCorsPolicySetRejectsUnmatchedWhenConfigured(t *testing.T) {
+	called := false
+	ps := &CorsPolicySet{
+		RejectUnmatchedRequests: true,
+		router:                  &fakeRouter{route: nil},
+	}
+
+	handler := ps.MiddlewareFunc(func(w ResponseWriter, r *Request) {
+		called = true
+	})
+
+	w := httptest.NewRecorder()
+	request := newTestRequest("GET", "/unknown")
+	request.Header.Set("Origin", "http://example.com")
+	handler(&responseWriter{ResponseWriter: w}, request)
+
+	if called {
+		t.Fatalf("expected the wrapped handler not to be called for an unmatched request")
+	}
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected status %d, got %d", http.StatusForbidden, w.Code)
+	}
+}
@@ -0,0 +1,134 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func varyContains(header http.Header, value string) bool {
+	for _, v := range header.Values("Vary") {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCorsMiddlewareSetsVaryOnRejectedOrigin(t *testing.T) {
+	mw := &CorsMiddleware{
+		OriginValidator: func(origin string, request *Request) bool { return false },
+	}
+	handler := mw.MiddlewareFunc(func(w ResponseWriter, r *Request) {
+		t.Fatalf("wrapped handler should not be called for a rejected Origin")
+	})
+
+	w := httptest.NewRecorder()
+	request := newTestRequest("GET", "http://api.example.com/foo")
+	request.Header.Set("Origin", "http://evil.example.com")
+	handler(&responseWriter{ResponseWriter: w}, request)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected status %d, got %d", http.StatusForbidden, w.Code)
+	}
+	if !varyContains(w.Header(), "Origin") {
+		t.Errorf("expected Vary: Origin on a rejected-Origin response, got %v", w.Header()["Vary"])
+	}
+}
+
+func TestCorsMiddlewareSetsVaryOnRejectedPreflight(t *testing.T) {
+	mw := &CorsMiddleware{
+		OriginValidator: func(origin string, request *Request) bool { return true },
+		AllowedMethods:  []string{"GET"},
+	}
+	handler := mw.MiddlewareFunc(func(w ResponseWriter, r *Request) {
+		t.Fatalf("wrapped handler should not be called for a rejected preflight")
+	})
+
+	w := httptest.NewRecorder()
+	request := newTestRequest("OPTIONS", "http://api.example.com/foo")
+	request.Header.Set("Origin", "http://example.com")
+	request.Header.Set("Access-Control-Request-Method", "DELETE")
+	handler(&responseWriter{ResponseWriter: w}, request)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected status %d, got %d", http.StatusForbidden, w.Code)
+	}
+	if !varyContains(w.Header(), "Origin") {
+		t.Errorf("expected Vary: Origin on a rejected preflight response, got %v", w.Header()["Vary"])
+	}
+	if !varyContains(w.Header(), "Access-Control-Request-Method, Access-Control-Request-Headers") {
+		t.Errorf("expected Vary: Access-Control-Request-Method, Access-Control-Request-Headers on a rejected preflight response, got %v", w.Header()["Vary"])
+	}
+}
+
+func TestCorsMiddlewareWildcardOriginWithoutCredentials(t *testing.T) {
+	mw := &CorsMiddleware{AllowedOrigins: []string{"*"}}
+	handler := mw.MiddlewareFunc(func(w ResponseWriter, r *Request) {})
+
+	w := httptest.NewRecorder()
+	request := newTestRequest("GET", "http://api.example.com/foo")
+	request.Header.Set("Origin", "http://example.com")
+	handler(&responseWriter{ResponseWriter: w}, request)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("expected Access-Control-Allow-Origin %q, got %q", "*", got)
+	}
+}
+
+func TestCorsMiddlewareWildcardOriginWithCredentialsEchoesOrigin(t *testing.T) {
+	mw := &CorsMiddleware{
+		AllowedOrigins:                []string{"*"},
+		AccessControlAllowCredentials: true,
+	}
+	handler := mw.MiddlewareFunc(func(w ResponseWriter, r *Request) {})
+
+	w := httptest.NewRecorder()
+	request := newTestRequest("GET", "http://api.example.com/foo")
+	request.Header.Set("Origin", "http://example.com")
+	handler(&responseWriter{ResponseWriter: w}, request)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "http://example.com" {
+		t.Errorf("expected Access-Control-Allow-Origin to echo the Origin (%q), got %q", "http://example.com", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("expected Access-Control-Allow-Credentials %q, got %q", "true", got)
+	}
+}
+
+func TestCorsMiddlewareMaxAgeOmittedWhenZero(t *testing.T) {
+	mw := &CorsMiddleware{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET"},
+	}
+	handler := mw.MiddlewareFunc(func(w ResponseWriter, r *Request) {})
+
+	w := httptest.NewRecorder()
+	request := newTestRequest("OPTIONS", "http://api.example.com/foo")
+	request.Header.Set("Origin", "http://example.com")
+	request.Header.Set("Access-Control-Request-Method", "GET")
+	handler(&responseWriter{ResponseWriter: w}, request)
+
+	if got := w.Header().Get("Access-Control-Max-Age"); got != "" {
+		t.Errorf("expected Access-Control-Max-Age to be omitted when 0, got %q", got)
+	}
+}
+
+func TestCorsMiddlewareMaxAgeSetAsDecimalString(t *testing.T) {
+	mw := &CorsMiddleware{
+		AllowedOrigins:      []string{"*"},
+		AllowedMethods:      []string{"GET"},
+		AccessControlMaxAge: 600,
+	}
+	handler := mw.MiddlewareFunc(func(w ResponseWriter, r *Request) {})
+
+	w := httptest.NewRecorder()
+	request := newTestRequest("OPTIONS", "http://api.example.com/foo")
+	request.Header.Set("Origin", "http://example.com")
+	request.Header.Set("Access-Control-Request-Method", "GET")
+	handler(&responseWriter{ResponseWriter: w}, request)
+
+	if got := w.Header().Get("Access-Control-Max-Age"); got != "600" {
+		t.Errorf("expected Access-Control-Max-Age %q, got %q (this was the old string(int) rune-conversion bug)", "600", got)
+	}
+}